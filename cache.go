@@ -1,6 +1,7 @@
 package cache
 
 import (
+	"container/list"
 	"time"
 	"sync"
 	"fmt"
@@ -31,10 +32,60 @@ const (
 
 type Cache struct {
 	defaultExpiration time.Duration
-	items            map[string]Item //缓存数据存储在Map中
+	store            Store           //数据项实际存储的后端，默认为 MemoryStore
 	mu               sync.RWMutex    //读写锁
 	gcInterval       time.Duration   //过期数据项清理周期
 	stopGc            chan bool
+
+	// 以下字段仅在通过 NewCacheWithCapacity 开启容量限制模式时使用，
+	// maxBytes <= 0 表示不限制容量，所有相关逻辑都会被跳过
+	maxBytes   int64                    //允许占用的最大字节数
+	usedBytes  int64                    //当前估算占用的字节数
+	policy     EvictionPolicy           //达到容量上限时使用的淘汰策略
+	evictList  *list.List               //LRU 顺序链表，表头为最近访问
+	elements   map[string]*list.Element //key 到链表节点的索引，用于 O(1) 定位
+	sizes      map[string]int64         //每个 key 的估算大小
+
+	// OnEvicted 在数据项被淘汰（过期、容量不足或手动删除）时调用，
+	// 调用时不持有锁，可以在回调里安全地访问 Cache 的方法
+	OnEvicted func(key string, v interface{}, reason EvictReason)
+
+	// 以下字段控制 DeleteExpired 的自适应抽样清理，见 NewCache 的 Option
+	sampleSize        int              //每轮抽样扫描的 key 数量
+	sweepBudget       time.Duration    //单次 tick 内重复抽样的时间预算
+	useExpirationHeap bool             //是否额外维护一个按过期时间排序的最小堆
+	expHeap           *expirationHeap
+	expHeapStale      int //自上次重建以来 set/delete 的次数，是 expHeap 陈旧记录数的上界，见 invalidateExpirationTracking
+}
+
+// EvictionPolicy 表示容量受限模式下使用的淘汰策略
+type EvictionPolicy int
+
+const (
+	EvictionLRU EvictionPolicy = iota //最近最少使用
+	EvictionLFU                       //最不经常使用
+)
+
+// EvictReason 表示一个数据项被淘汰的原因
+type EvictReason int
+
+const (
+	EvictReasonExpired  EvictReason = iota //因为过期被清理
+	EvictReasonCapacity                    //因为超出容量被淘汰
+	EvictReasonManual                      //被主动调用 Delete 删除
+)
+
+func (r EvictReason) String() string {
+	switch r {
+	case EvictReasonExpired:
+		return "expired"
+	case EvictReasonCapacity:
+		return "capacity"
+	case EvictReasonManual:
+		return "manual"
+	default:
+		return "unknown"
+	}
 }
 
 func (c *Cache) gcLoop()  {
@@ -54,26 +105,60 @@ func (c *Cache) gcLoop()  {
 }
 //删除缓存过期项
 func (c *Cache) delete(k string)  {
-	delete(c.items, k)
+	c.store.Delete(k)
+	c.invalidateExpirationTracking()
 }
 
 
-//删除过期数据项
+//删除过期数据项。不再是每次 tick 都全量扫描 items，而是先从过期堆里弹出
+//已确定过期的 key（如果开启了 WithExpirationHeap），再对 store 做抽样清理：
+//抽样里过期比例达到阈值就不释放锁继续抽样，直到过期比例降到阈值以下或达到 sweepBudget
 func (c *Cache) DeleteExpired()  {
-	now :=time.Now().UnixNano()
+	now := time.Now().UnixNano()
+	deadline := time.Now().Add(c.sweepBudget)
+	var allEvicted []evictedEntry
+
 	c.mu.Lock()
-	defer c.mu.Unlock()
+	_, heapEvicted := c.popExpiredFromHeap(now)
+	allEvicted = append(allEvicted, heapEvicted...)
 
-	for k,v := range c.items {
-		if v.Expiration > 0 && now > v.Expiration {
+	for {
+		expired, evicted, sampled := c.sampleExpired(now)
+		for _, k := range expired {
 			c.delete(k)
+			if c.maxBytes > 0 {
+				c.removeElement(k)
+			}
+		}
+		allEvicted = append(allEvicted, evicted...)
+
+		if sampled == 0 {
+			break
+		}
+		ratio := float64(len(expired)) / float64(sampled)
+		if ratio < expiredSampleRatio || time.Now().After(deadline) {
+			break
 		}
+	}
+	c.mu.Unlock()
 
+	for _, e := range allEvicted {
+		c.OnEvicted(e.key, e.object, EvictReasonExpired)
 	}
 }
 
 //设置缓存数据项，如果数据存在则覆盖
 func (c *Cache) Set(k string, v interface{}, d time.Duration) {
+	c.mu.Lock()
+	evicted := c.set(k, v, d)
+	c.mu.Unlock()
+	c.notifyEvicted(evicted)
+}
+
+//set 是 Set 去掉加锁之后的实现，调用方必须已经持有 c.mu 的写锁。
+//Add/Replace 复用它而不是直接调用 Set，因为 c.mu 不是可重入锁，
+//在已经持有写锁时再调用 Set 会自己把自己锁死
+func (c *Cache) set(k string, v interface{}, d time.Duration) []evictedEntry {
 	var e int64
 	if d == DefaultExpiration{
 		d = c.defaultExpiration
@@ -81,17 +166,34 @@ func (c *Cache) Set(k string, v interface{}, d time.Duration) {
 	if d > 0 {
 		e = time.Now().Add(d).UnixNano()
 	}
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	c.items[k] = Item{
+	c.store.Set(k, Item{
 		Object:  v,
 		Expiration: e,
+	})
+	c.invalidateExpirationTracking()
+	c.trackExpiration(k, e)
+	if c.maxBytes > 0 {
+		c.trackSet(k, v)
+		return c.evictToFit()
 	}
+	return nil
+}
 
+//writeBack 把 item 写回 store 并在容量受限模式下刷新它的 LRU/LFU 记账，
+//过期时间没有变化，所以不会碰 expHeap。供 Increment/Decrement/IncrementFloat
+//复用，这样数值类型的原地修改也会参与容量统计和淘汰，和 Set 保持一致。
+//调用方必须持有 c.mu 的写锁
+func (c *Cache) writeBack(k string, item Item) []evictedEntry {
+	c.store.Set(k, item)
+	if c.maxBytes > 0 {
+		c.trackSet(k, item.Object)
+		return c.evictToFit()
+	}
+	return nil
 }
 //获取数据项，如果找到数据项，还需要判断该数据项是否已经过期
 func (c *Cache) get(k string) (interface{}, bool) {
-	item, found :=c.items[k]
+	item, found :=c.store.Get(k)
 	if !found {
 		return nil, false
 	}
@@ -110,17 +212,29 @@ func (c *Cache) Add(k string, v interface{}, d time.Duration) error  {
 		c.mu.Unlock()
 		return fmt.Errorf("item %s already exists", k)
 	}
-	c.Set(k, v, d)
+	evicted := c.set(k, v, d)
 	c.mu.Unlock()
+	c.notifyEvicted(evicted)
 	return nil
 }
 
 //获取数据项
 func (c *Cache) Get(k string) (interface{}, bool)  {
+	// 容量受限模式下访问会调整 LRU/LFU 顺序，需要写锁
+	if c.maxBytes > 0 {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		v, found := c.get(k)
+		if found {
+			c.touch(k)
+		}
+		return v, found
+	}
+
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
-	item, found :=c.items[k]
+	item, found :=c.store.Get(k)
 	if !found {
 		return nil, false
 	}
@@ -138,16 +252,24 @@ func (c *Cache) Replace(k string, v interface{}, d time.Duration) error  {
 		c.mu.Unlock()
 		return fmt.Errorf("Item %s doesn`t exist", k)
 	}
-	c.Set(k,v, d)
+	evicted := c.set(k, v, d)
 	c.mu.Unlock()
+	c.notifyEvicted(evicted)
 	return nil
 }
 //删除一个数据项
 func (c *Cache) Delete(k string)  {
 	c.mu.Lock()
+	v, found := c.store.Get(k)
 	c.delete(k)
+	if c.maxBytes > 0 {
+		c.removeElement(k)
+	}
 	c.mu.Unlock()
-	
+
+	if found && c.OnEvicted != nil {
+		c.OnEvicted(k, v.Object, EvictReasonManual)
+	}
 }
 //将缓存数据写入io.write中
 func (c *Cache) Save(w io.Writer)(err error)  {
@@ -159,21 +281,39 @@ func (c *Cache) Save(w io.Writer)(err error)  {
 	}()
 	c.mu.RLock()
 	defer c.mu.RUnlock()
-	for _, v :=range c.items{
+	items := map[string]Item{}
+	c.store.Range(func(k string, v Item) bool {
 		gob.Register(v.Object)
-	}
-	err = enc.Encode(&c.items)
+		items[k] = v
+		return true
+	})
+	err = enc.Encode(&items)
 	return
 }
 
-//保存数据项到文件里
+//保存数据项到文件里，先写入临时文件再 fsync + rename，
+//保证中途崩溃不会留下一个损坏的快照
 func (c *Cache) SaveToFile(file string) error {
-	f,err := os.Create(file)
+	tmp := file + ".tmp"
+	f, err := os.Create(tmp)
 	if err != nil {
 		return err
 	}
-	return f.Close()
-
+	if err = c.Save(f); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err = f.Sync(); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err = f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, file)
 }
 
 //从io。writer读取数据项
@@ -185,9 +325,9 @@ func (c *Cache) Load(r io.Reader) error{
 		c.mu.Lock()
 		defer c.mu.Unlock()
 		for k,v := range items{
-			ov, found := c.items[k]
+			ov, found := c.store.Get(k)
 			if !found || ov.Expired(){
-				c.items[k] = v
+				c.store.Set(k, v)
 			}
 
 		}
@@ -211,14 +351,34 @@ func (c *Cache) LoadFile(file string) error {
 func (c *Cache) Count() int {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
-	return len(c.items)
+	return c.store.Len()
+}
+
+// 返回当前缓存估算占用的字节数，仅在 NewCacheWithCapacity 创建的容量受限缓存上有意义
+func (c *Cache) Bytes() int64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.usedBytes
 }
 
 // 清空缓存
 func (c *Cache) Flush() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	c.items = map[string]Item{}
+	var keys []string
+	c.store.Range(func(k string, v Item) bool {
+		keys = append(keys, k)
+		return true
+	})
+	for _, k := range keys {
+		c.store.Delete(k)
+	}
+	if c.maxBytes > 0 {
+		c.evictList.Init()
+		c.elements = map[string]*list.Element{}
+		c.sizes = map[string]int64{}
+		c.usedBytes = 0
+	}
 }
 
 // 停止过期缓存清理
@@ -226,13 +386,19 @@ func (c *Cache) StopGc() {
 	c.stopGc <- true
 }
 
-// 创建一个缓存系统
-func NewCache(defaultExpiration, gcInterval time.Duration) *Cache {
+// 创建一个缓存系统，可以通过 opts 调整过期清理的抽样大小、时间预算，
+// 或者开启按过期时间排序的堆（见 WithSampleSize/WithSweepBudget/WithExpirationHeap）
+func NewCache(defaultExpiration, gcInterval time.Duration, opts ...Option) *Cache {
 	c := &Cache{
 		defaultExpiration: defaultExpiration,
 		gcInterval:        gcInterval,
-		items:             map[string]Item{},
+		store:             NewMemoryStore(),
 		stopGc:            make(chan bool),
+		sampleSize:        defaultSampleSize,
+		sweepBudget:       defaultSweepBudget,
+	}
+	for _, opt := range opts {
+		opt(c)
 	}
 	// 开始启动过期清理 goroutine
 	go c.gcLoop()
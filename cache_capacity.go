@@ -0,0 +1,182 @@
+package cache
+
+import (
+	"bytes"
+	"container/list"
+	"encoding/gob"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unsafe"
+)
+
+// entry 是 evictList 中每个节点保存的内容，用于 LRU 定位以及 LFU 计数
+type entry struct {
+	key  string
+	freq int64
+}
+
+// evictedEntry 记录一次被淘汰的数据项，用于在释放锁之后再调用 OnEvicted
+type evictedEntry struct {
+	key    string
+	object interface{}
+}
+
+// NewCacheWithCapacity 创建一个带容量限制的缓存，写入数据超出 maxBytes 时
+// 会按照 policy 指定的策略（EvictionLRU 或 EvictionLFU）淘汰旧数据直到不超限
+func NewCacheWithCapacity(defaultExpiration, gcInterval time.Duration, maxBytes int64, policy EvictionPolicy) *Cache {
+	c := NewCache(defaultExpiration, gcInterval)
+	c.maxBytes = maxBytes
+	c.policy = policy
+	c.evictList = list.New()
+	c.elements = map[string]*list.Element{}
+	c.sizes = map[string]int64{}
+	return c
+}
+
+// ParseSize 解析形如 "1MB"、"100KB"、"2GB" 的容量字符串，返回对应的字节数。
+// 不带单位时按字节数解析
+func ParseSize(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, fmt.Errorf("cache: empty size string")
+	}
+	upper := strings.ToUpper(s)
+	units := []struct {
+		suffix string
+		factor int64
+	}{
+		{"GB", 1024 * 1024 * 1024},
+		{"MB", 1024 * 1024},
+		{"KB", 1024},
+		{"B", 1},
+	}
+	for _, u := range units {
+		if strings.HasSuffix(upper, u.suffix) {
+			numPart := strings.TrimSpace(strings.TrimSuffix(upper, u.suffix))
+			n, err := strconv.ParseFloat(numPart, 64)
+			if err != nil {
+				return 0, fmt.Errorf("cache: invalid size %q: %v", s, err)
+			}
+			return int64(n * float64(u.factor)), nil
+		}
+	}
+	n, err := strconv.ParseInt(upper, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("cache: invalid size %q", s)
+	}
+	return n, nil
+}
+
+// estimateSize 估算一个数据项占用的字节数。对常见类型直接计算，
+// 其它类型尝试用 gob 编码后的长度来近似，编码失败（比如类型未注册）时退回 unsafe.Sizeof
+func estimateSize(k string, v interface{}) int64 {
+	size := int64(len(k)) + int64(unsafe.Sizeof(Item{}))
+	switch val := v.(type) {
+	case nil:
+		return size
+	case string:
+		return size + int64(len(val))
+	case []byte:
+		return size + int64(len(val))
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(&v); err == nil {
+		return size + int64(buf.Len())
+	}
+	return size + int64(unsafe.Sizeof(v))
+}
+
+// trackSet 在容量受限模式下记录/更新一个 key 的大小，并把它放到 LRU 链表最前面
+func (c *Cache) trackSet(k string, v interface{}) {
+	if old, found := c.sizes[k]; found {
+		c.usedBytes -= old
+	}
+	newSize := estimateSize(k, v)
+	c.sizes[k] = newSize
+	c.usedBytes += newSize
+
+	if elem, found := c.elements[k]; found {
+		elem.Value.(*entry).freq++
+		c.evictList.MoveToFront(elem)
+		return
+	}
+	elem := c.evictList.PushFront(&entry{key: k, freq: 1})
+	c.elements[k] = elem
+}
+
+// touch 在读取一个 key 时更新它的 LRU/LFU 状态
+func (c *Cache) touch(k string) {
+	elem, found := c.elements[k]
+	if !found {
+		return
+	}
+	elem.Value.(*entry).freq++
+	c.evictList.MoveToFront(elem)
+}
+
+// removeElement 把一个 key 从容量统计与淘汰结构中移除
+func (c *Cache) removeElement(k string) {
+	if elem, found := c.elements[k]; found {
+		c.evictList.Remove(elem)
+		delete(c.elements, k)
+	}
+	if size, found := c.sizes[k]; found {
+		c.usedBytes -= size
+		delete(c.sizes, k)
+	}
+}
+
+// evictToFit 在超出 maxBytes 时不断淘汰数据项直到占用回到限制以内，
+// 返回被淘汰的数据项列表，调用方需要在释放锁之后再触发 OnEvicted
+func (c *Cache) evictToFit() []evictedEntry {
+	var evicted []evictedEntry
+	for c.usedBytes > c.maxBytes && c.evictList.Len() > 0 {
+		key := c.victimKey()
+		if key == "" {
+			break
+		}
+		item, _ := c.store.Get(key)
+		c.delete(key)
+		c.removeElement(key)
+		evicted = append(evicted, evictedEntry{key, item.Object})
+	}
+	return evicted
+}
+
+// victimKey 根据淘汰策略选出下一个应该被淘汰的 key
+func (c *Cache) victimKey() string {
+	if c.policy == EvictionLFU {
+		return c.lfuVictim()
+	}
+	if back := c.evictList.Back(); back != nil {
+		return back.Value.(*entry).key
+	}
+	return ""
+}
+
+// lfuVictim 扫描所有节点找到访问频次最低的 key
+func (c *Cache) lfuVictim() string {
+	var victim string
+	var minFreq int64 = -1
+	for k, elem := range c.elements {
+		f := elem.Value.(*entry).freq
+		if minFreq == -1 || f < minFreq {
+			minFreq = f
+			victim = k
+		}
+	}
+	return victim
+}
+
+// notifyEvicted 在释放锁之后调用 OnEvicted，避免回调里再次访问 Cache 时死锁
+func (c *Cache) notifyEvicted(evicted []evictedEntry) {
+	if c.OnEvicted == nil {
+		return
+	}
+	for _, e := range evicted {
+		c.OnEvicted(e.key, e.object, EvictReasonCapacity)
+	}
+}
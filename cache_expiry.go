@@ -0,0 +1,161 @@
+package cache
+
+import (
+	"container/heap"
+	"time"
+)
+
+const (
+	defaultSampleSize  = 20                     // 每轮抽样扫描的默认 key 数量
+	defaultSweepBudget = 25 * time.Millisecond  // 单次 tick 内重复抽样的默认时间预算
+	expiredSampleRatio = 0.25                   // 抽样中过期比例超过该阈值就继续抽样
+
+	// expHeapCompactThreshold 是 expHeap 里累积的陈旧记录数量达到多少时才
+	// 考虑整堆重建，避免频繁覆盖同一批 TTL key 时每次 Set 都重建一次堆
+	expHeapCompactThreshold = 1024
+)
+
+// Option 是创建 Cache 时用来调整过期清理行为的函数式选项
+type Option func(*Cache)
+
+// WithSampleSize 设置每轮过期扫描抽样的 key 数量，默认 20
+func WithSampleSize(n int) Option {
+	return func(c *Cache) {
+		if n > 0 {
+			c.sampleSize = n
+		}
+	}
+}
+
+// WithSweepBudget 设置单次 tick 内允许重复抽样清理的最长耗时，默认 25ms
+func WithSweepBudget(d time.Duration) Option {
+	return func(c *Cache) {
+		if d > 0 {
+			c.sweepBudget = d
+		}
+	}
+}
+
+// WithExpirationHeap 打开/关闭按过期时间维护的最小堆；开启后 DeleteExpired
+// 会先从堆顶弹出已经过期的 key，不需要扫描就能及时清理长尾的 TTL 数据
+func WithExpirationHeap(enabled bool) Option {
+	return func(c *Cache) {
+		c.useExpirationHeap = enabled
+		if enabled && c.expHeap == nil {
+			c.expHeap = &expirationHeap{}
+			heap.Init(c.expHeap)
+		}
+	}
+}
+
+// expirationEntry 是过期堆中的一个节点，记录某个 key 在被 Set 时写入的过期时间
+type expirationEntry struct {
+	key        string
+	expiration int64
+}
+
+// expirationHeap 是按 expiration 排序的最小堆，堆顶永远是最早过期的 key。
+// Set/Delete 不会主动从堆里摘除旧记录，堆顶被弹出时才核对是否还有效（惰性清理）
+type expirationHeap []expirationEntry
+
+func (h expirationHeap) Len() int           { return len(h) }
+func (h expirationHeap) Less(i, j int) bool { return h[i].expiration < h[j].expiration }
+func (h expirationHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *expirationHeap) Push(x interface{}) {
+	*h = append(*h, x.(expirationEntry))
+}
+
+func (h *expirationHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
+
+// trackExpiration 在 Set 一个带有限 TTL 的 key 时把它推入过期堆，
+// 调用方必须持有 c.mu
+func (c *Cache) trackExpiration(k string, exp int64) {
+	if !c.useExpirationHeap || exp <= 0 {
+		return
+	}
+	heap.Push(c.expHeap, expirationEntry{key: k, expiration: exp})
+}
+
+// invalidateExpirationTracking 在每次 set 和 delete 时调用：被覆盖或删除的 key
+// 之前如果在 expHeap 里有记录，那条记录就变成了陈旧数据，只能等到被弹到堆顶
+// 时才会被惰性丢弃。重复 Set/Delete 同一批 TTL key（TTL churn）会让堆不断积累
+// 这种陈旧记录。这里不区分 key 原本是否存在，每次调用都计一次，所以计数是
+// 陈旧记录数的上界；计数累积到阈值、并且堆的长度超过存活 key 数量的两倍时，
+// 整堆重建一次，丢掉所有陈旧记录。调用方必须持有 c.mu
+func (c *Cache) invalidateExpirationTracking() {
+	if !c.useExpirationHeap {
+		return
+	}
+	c.expHeapStale++
+	if c.expHeapStale >= expHeapCompactThreshold && c.expHeap.Len() > 2*c.store.Len() {
+		c.compactExpirationHeap()
+	}
+}
+
+// compactExpirationHeap 用 store 里当前的数据重新构建 expHeap，只保留仍然
+//有限 TTL 的 key，调用方必须持有 c.mu
+func (c *Cache) compactExpirationHeap() {
+	fresh := &expirationHeap{}
+	c.store.Range(func(k string, v Item) bool {
+		if v.Expiration > 0 {
+			*fresh = append(*fresh, expirationEntry{key: k, expiration: v.Expiration})
+		}
+		return true
+	})
+	heap.Init(fresh)
+	c.expHeap = fresh
+	c.expHeapStale = 0
+}
+
+// popExpiredFromHeap 不断弹出堆顶记录，删除其中仍然有效（没有被覆盖或删除）
+// 且已经过期的 key；堆顶尚未过期时停止。调用方必须持有 c.mu
+func (c *Cache) popExpiredFromHeap(now int64) (expiredKeys []string, evicted []evictedEntry) {
+	if !c.useExpirationHeap || c.expHeap == nil {
+		return nil, nil
+	}
+	for c.expHeap.Len() > 0 {
+		top := (*c.expHeap)[0]
+		if top.expiration > now {
+			break
+		}
+		heap.Pop(c.expHeap)
+
+		item, found := c.store.Get(top.key)
+		if !found || item.Expiration != top.expiration {
+			// 陈旧记录：key 已被删除，或者之后又被 Set 过，忽略它
+			continue
+		}
+		c.store.Delete(top.key)
+		if c.maxBytes > 0 {
+			c.removeElement(top.key)
+		}
+		expiredKeys = append(expiredKeys, top.key)
+		if c.OnEvicted != nil {
+			evicted = append(evicted, evictedEntry{top.key, item.Object})
+		}
+	}
+	return expiredKeys, evicted
+}
+
+// sampleExpired 从 store 里抽样最多 sampleSize 个 key（借助 map 遍历顺序本身
+// 是随机的这一点），返回其中已经过期的 key 以及抽样总数。调用方必须持有 c.mu
+func (c *Cache) sampleExpired(now int64) (expiredKeys []string, evicted []evictedEntry, sampled int) {
+	c.store.Range(func(k string, v Item) bool {
+		sampled++
+		if v.Expiration > 0 && now > v.Expiration {
+			expiredKeys = append(expiredKeys, k)
+			if c.OnEvicted != nil {
+				evicted = append(evicted, evictedEntry{k, v.Object})
+			}
+		}
+		return sampled < c.sampleSize
+	})
+	return
+}
@@ -0,0 +1,212 @@
+package cache
+
+import (
+	"fmt"
+	"time"
+)
+
+// Increment 对存储的数值类型数据项原地加 n，支持 int/intN/uint/uintN/float32/64，
+// key 不存在或者值不是数值类型时返回错误
+func (c *Cache) Increment(k string, n int64) (int64, error) {
+	c.mu.Lock()
+	item, found := c.store.Get(k)
+	if !found || item.Expired() {
+		c.mu.Unlock()
+		return 0, fmt.Errorf("item %s not found", k)
+	}
+
+	var result int64
+	switch v := item.Object.(type) {
+	case int:
+		v += int(n)
+		result, item.Object = int64(v), v
+	case int8:
+		v += int8(n)
+		result, item.Object = int64(v), v
+	case int16:
+		v += int16(n)
+		result, item.Object = int64(v), v
+	case int32:
+		v += int32(n)
+		result, item.Object = int64(v), v
+	case int64:
+		v += n
+		result, item.Object = v, v
+	case uint:
+		v += uint(n)
+		result, item.Object = int64(v), v
+	case uintptr:
+		v += uintptr(n)
+		result, item.Object = int64(v), v
+	case uint8:
+		v += uint8(n)
+		result, item.Object = int64(v), v
+	case uint16:
+		v += uint16(n)
+		result, item.Object = int64(v), v
+	case uint32:
+		v += uint32(n)
+		result, item.Object = int64(v), v
+	case uint64:
+		v += uint64(n)
+		result, item.Object = int64(v), v
+	case float32:
+		v += float32(n)
+		result, item.Object = int64(v), v
+	case float64:
+		v += float64(n)
+		result, item.Object = int64(v), v
+	default:
+		c.mu.Unlock()
+		return 0, fmt.Errorf("item %s is not an integer or float", k)
+	}
+	evicted := c.writeBack(k, item)
+	c.mu.Unlock()
+	c.notifyEvicted(evicted)
+	return result, nil
+}
+
+// Decrement 对存储的数值类型数据项原地减 n，类型支持和错误处理与 Increment 相同
+func (c *Cache) Decrement(k string, n int64) (int64, error) {
+	c.mu.Lock()
+	item, found := c.store.Get(k)
+	if !found || item.Expired() {
+		c.mu.Unlock()
+		return 0, fmt.Errorf("item %s not found", k)
+	}
+
+	var result int64
+	switch v := item.Object.(type) {
+	case int:
+		v -= int(n)
+		result, item.Object = int64(v), v
+	case int8:
+		v -= int8(n)
+		result, item.Object = int64(v), v
+	case int16:
+		v -= int16(n)
+		result, item.Object = int64(v), v
+	case int32:
+		v -= int32(n)
+		result, item.Object = int64(v), v
+	case int64:
+		v -= n
+		result, item.Object = v, v
+	case uint:
+		v -= uint(n)
+		result, item.Object = int64(v), v
+	case uintptr:
+		v -= uintptr(n)
+		result, item.Object = int64(v), v
+	case uint8:
+		v -= uint8(n)
+		result, item.Object = int64(v), v
+	case uint16:
+		v -= uint16(n)
+		result, item.Object = int64(v), v
+	case uint32:
+		v -= uint32(n)
+		result, item.Object = int64(v), v
+	case uint64:
+		v -= uint64(n)
+		result, item.Object = int64(v), v
+	case float32:
+		v -= float32(n)
+		result, item.Object = int64(v), v
+	case float64:
+		v -= float64(n)
+		result, item.Object = int64(v), v
+	default:
+		c.mu.Unlock()
+		return 0, fmt.Errorf("item %s is not an integer or float", k)
+	}
+	evicted := c.writeBack(k, item)
+	c.mu.Unlock()
+	c.notifyEvicted(evicted)
+	return result, nil
+}
+
+// IncrementFloat 对存储的 float32/float64 数据项原地加 n，返回加完之后的值
+func (c *Cache) IncrementFloat(k string, n float64) (float64, error) {
+	c.mu.Lock()
+	item, found := c.store.Get(k)
+	if !found || item.Expired() {
+		c.mu.Unlock()
+		return 0, fmt.Errorf("item %s not found", k)
+	}
+
+	var result float64
+	switch v := item.Object.(type) {
+	case float32:
+		v += float32(n)
+		result, item.Object = float64(v), v
+	case float64:
+		v += n
+		result, item.Object = v, v
+	default:
+		c.mu.Unlock()
+		return 0, fmt.Errorf("item %s does not hold a float32 or float64", k)
+	}
+	evicted := c.writeBack(k, item)
+	c.mu.Unlock()
+	c.notifyEvicted(evicted)
+	return result, nil
+}
+
+// GetWithExpiration 和 Get 一样返回数据项的值，此外还返回它的过期时间；
+// 没有设置过期时间的数据项返回零值 time.Time
+func (c *Cache) GetWithExpiration(k string) (interface{}, time.Time, bool) {
+	if c.maxBytes > 0 {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		item, found := c.store.Get(k)
+		if !found || item.Expired() {
+			return nil, time.Time{}, false
+		}
+		c.touch(k)
+		return item.Object, itemExpirationTime(item), true
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	item, found := c.store.Get(k)
+	if !found || item.Expired() {
+		return nil, time.Time{}, false
+	}
+	return item.Object, itemExpirationTime(item), true
+}
+
+// itemExpirationTime 把 Item.Expiration 的纳秒时间戳转换成 time.Time，
+// 没有过期时间（Expiration == 0）时返回零值
+func itemExpirationTime(item Item) time.Time {
+	if item.Expiration == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, item.Expiration)
+}
+
+// Items 返回当前所有未过期数据项的一份拷贝，适合用于外部序列化，
+// 修改返回的 map 不会影响缓存本身
+func (c *Cache) Items() map[string]Item {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	items := map[string]Item{}
+	c.store.Range(func(k string, v Item) bool {
+		if !v.Expired() {
+			items[k] = v
+		}
+		return true
+	})
+	return items
+}
+
+// NewFrom 创建一个缓存并直接用 items 填充它，用来在不经过 gob 的情况下
+// 从一份快照（比如 Items() 的返回值）恢复缓存
+func NewFrom(defaultExpiration, gcInterval time.Duration, items map[string]Item) *Cache {
+	c := NewCache(defaultExpiration, gcInterval)
+	for k, v := range items {
+		c.store.Set(k, v)
+		c.trackExpiration(k, v.Expiration)
+	}
+	return c
+}
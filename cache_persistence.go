@@ -0,0 +1,50 @@
+package cache
+
+import (
+	"encoding/gob"
+	"sync"
+	"time"
+)
+
+// NewCacheFromFile 创建一个缓存并立即从 file 中加载快照，用于进程启动时
+// 快速恢复上一次 SaveToFile/AutoPersist 留下的数据。加载失败时会先停掉
+// NewCache 已经启动的 gc goroutine 再返回错误，避免泄漏
+func NewCacheFromFile(defaultExpiration, gcInterval time.Duration, file string) (*Cache, error) {
+	c := NewCache(defaultExpiration, gcInterval)
+	if err := c.LoadFile(file); err != nil {
+		c.StopGc()
+		return nil, err
+	}
+	return c, nil
+}
+
+// AutoPersist 启动一个后台 goroutine，每隔 interval 把缓存快照写入 file，
+// 返回的 stop 函数用于停止该 goroutine。stop 用 sync.Once 包了一层，
+// 多次调用也不会因为重复 close(done) 而 panic
+func (c *Cache) AutoPersist(file string, interval time.Duration) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan bool)
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				c.SaveToFile(file)
+			case <-done:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+		})
+	}
+}
+
+// RegisterType 向 gob 注册一个非基础类型，保存/加载包含该类型的缓存数据前
+// 需要先注册一次，否则 Save/Load 会因为类型未知而失败
+func RegisterType(v interface{}) {
+	gob.Register(v)
+}
@@ -0,0 +1,161 @@
+// Package cacheserver 把一个 *cache.Cache 通过 TCP 暴露出来，提供 Memcached
+// 文本协议的一个子集（set/add/replace/get/delete/flush_all/stats），这样这个
+// 模块也可以当成一个独立的缓存守护进程来使用
+package cacheserver
+
+import (
+	"bufio"
+	"cache"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Server 把一个 *cache.Cache 包装成可以监听 TCP 连接的 Memcached 兼容服务
+type Server struct {
+	cache *cache.Cache
+}
+
+// New 创建一个包装了 c 的 Server
+func New(c *cache.Cache) *Server {
+	return &Server{cache: c}
+}
+
+// ListenAndServe 在 addr 上监听并处理客户端连接，直到 Accept 返回致命错误
+func ListenAndServe(addr string, c *cache.Cache) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return New(c).Serve(ln)
+}
+
+// Serve 在 ln 上接受连接，每个连接用一个 goroutine 处理
+func (s *Server) Serve(ln net.Listener) error {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return err
+		}
+		go s.handleConn(conn)
+	}
+}
+
+func (s *Server) handleConn(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "set", "add", "replace":
+			s.handleStore(conn, r, fields)
+		case "get":
+			s.handleGet(conn, fields[1:])
+		case "delete":
+			s.handleDelete(conn, fields[1:])
+		case "flush_all":
+			s.cache.Flush()
+			fmt.Fprint(conn, "OK\r\n")
+		case "stats":
+			fmt.Fprintf(conn, "STAT curr_items %d\r\nEND\r\n", s.cache.Count())
+		default:
+			fmt.Fprint(conn, "ERROR\r\n")
+		}
+	}
+}
+
+// handleStore 处理 "set|add|replace <key> <flags> <exptime> <bytes>\r\n<data>\r\n"，
+// flags 不影响存储，只是原样被协议要求携带
+func (s *Server) handleStore(conn net.Conn, r *bufio.Reader, fields []string) {
+	if len(fields) != 5 {
+		fmt.Fprint(conn, "ERROR\r\n")
+		return
+	}
+	key := fields[1]
+	exptime, err1 := strconv.ParseInt(fields[3], 10, 64)
+	n, err2 := strconv.Atoi(fields[4])
+	if err1 != nil || err2 != nil {
+		fmt.Fprint(conn, "ERROR\r\n")
+		return
+	}
+
+	data := make([]byte, n+2)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return
+	}
+	value := string(data[:n])
+	d := expTimeToDuration(exptime)
+
+	var err error
+	switch fields[0] {
+	case "set":
+		s.cache.Set(key, value, d)
+	case "add":
+		err = s.cache.Add(key, value, d)
+	case "replace":
+		err = s.cache.Replace(key, value, d)
+	}
+	if err != nil {
+		fmt.Fprint(conn, "NOT_STORED\r\n")
+		return
+	}
+	fmt.Fprint(conn, "STORED\r\n")
+}
+
+func (s *Server) handleGet(conn net.Conn, keys []string) {
+	for _, k := range keys {
+		v, found := s.cache.Get(k)
+		if !found {
+			continue
+		}
+		data := fmt.Sprint(v)
+		fmt.Fprintf(conn, "VALUE %s 0 %d\r\n%s\r\n", k, len(data), data)
+	}
+	fmt.Fprint(conn, "END\r\n")
+}
+
+func (s *Server) handleDelete(conn net.Conn, keys []string) {
+	if len(keys) == 0 {
+		fmt.Fprint(conn, "ERROR\r\n")
+		return
+	}
+	if _, found := s.cache.Get(keys[0]); !found {
+		fmt.Fprint(conn, "NOT_FOUND\r\n")
+		return
+	}
+	s.cache.Delete(keys[0])
+	fmt.Fprint(conn, "DELETED\r\n")
+}
+
+// expTimeToDuration 按 Memcached 的约定翻译 exptime：0 表示永不过期，
+// 30 天以内的正值当作相对秒数，更大的值当作绝对 Unix 时间戳，负值表示
+// 数据项应当立即失效，已经过去的绝对时间戳也一样。Cache.Set 把 d==0 解读成
+// "使用默认过期时间"、把 d<0 解读成永不过期，所以这里不能直接传 0 或负数，
+// 改为传一个极小的正值，让它落盘后几乎立刻过期
+func expTimeToDuration(exptime int64) time.Duration {
+	const thirtyDays = 60 * 60 * 24 * 30
+	switch {
+	case exptime < 0:
+		return time.Nanosecond
+	case exptime == 0:
+		return cache.NoExpiration
+	case exptime <= thirtyDays:
+		return time.Duration(exptime) * time.Second
+	default:
+		d := time.Until(time.Unix(exptime, 0))
+		if d <= 0 {
+			return time.Nanosecond
+		}
+		return d
+	}
+}
@@ -0,0 +1,37 @@
+// cache-server 是一个独立的缓存守护进程，通过 cacheserver 把一个
+// *cache.Cache 用 Memcached 文本协议暴露在 TCP 端口上
+package main
+
+import (
+	"cache"
+	"cache/cacheserver"
+	"flag"
+	"log"
+	"time"
+)
+
+func main() {
+	addr := flag.String("addr", ":11211", "TCP 监听地址")
+	defaultExpiration := flag.Duration("default-expiration", cache.NoExpiration, "没有指定 exptime 的数据项的默认过期时间")
+	gcInterval := flag.Duration("gc-interval", time.Minute, "过期数据清理周期")
+	snapshotFile := flag.String("snapshot", "", "可选，启动时从该文件加载快照，并周期性写回")
+	flag.Parse()
+
+	var c *cache.Cache
+	if *snapshotFile != "" {
+		loaded, err := cache.NewCacheFromFile(*defaultExpiration, *gcInterval, *snapshotFile)
+		if err != nil {
+			log.Printf("未能从 %s 加载快照，使用空缓存启动: %v", *snapshotFile, err)
+			loaded = cache.NewCache(*defaultExpiration, *gcInterval)
+		}
+		c = loaded
+		c.AutoPersist(*snapshotFile, time.Minute)
+	} else {
+		c = cache.NewCache(*defaultExpiration, *gcInterval)
+	}
+
+	log.Printf("cache-server listening on %s", *addr)
+	if err := cacheserver.ListenAndServe(*addr, c); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,133 @@
+//go:build !windows
+
+package cache
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"os"
+	"sync"
+	"syscall"
+)
+
+// FileStore 把数据项保存在一个 mmap 出来的文件里，重启进程后可以直接从磁盘
+// 恢复数据，而不像 MemoryStore 那样只存在于进程内存中。读写仍然先落在内存
+// 索引 items 上，Set/Delete 之后立即把整份快照通过 mmap 写回文件
+type FileStore struct {
+	mu    sync.Mutex
+	file  *os.File
+	items map[string]Item
+}
+
+// NewFileStore 打开（或创建）path 对应的文件，如果文件里已经有上一次
+// flush 留下的快照则据此恢复 items
+func NewFileStore(path string) (*FileStore, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	s := &FileStore{file: f, items: map[string]Item{}}
+	if err := s.load(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// load 把文件内容 mmap 进来并 gob 解码为 items，空文件视为没有历史数据
+func (s *FileStore) load() error {
+	info, err := s.file.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Size() == 0 {
+		return nil
+	}
+	data, err := syscall.Mmap(int(s.file.Fd()), 0, int(info.Size()), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return err
+	}
+	defer syscall.Munmap(data)
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(&s.items)
+}
+
+// flush 把当前 items 重新编码成一份快照，截断/扩展底层文件后 mmap 写回。
+// 存储 nil 值时 gob.Register(nil) 会 panic，所以跳过 nil，并且和 Cache.Save
+// 一样用 recover 兜底，避免一次注册失败拖垮整个进程
+func (s *FileStore) flush() (err error) {
+	defer func() {
+		if x := recover(); x != nil {
+			err = fmt.Errorf("使用Gob库注册项类型时出错")
+		}
+	}()
+	var buf bytes.Buffer
+	for _, v := range s.items {
+		if v.Object != nil {
+			gob.Register(v.Object)
+		}
+	}
+	if err := gob.NewEncoder(&buf).Encode(&s.items); err != nil {
+		return err
+	}
+	size := buf.Len()
+	if size == 0 {
+		return s.file.Truncate(0)
+	}
+	if err := s.file.Truncate(int64(size)); err != nil {
+		return err
+	}
+	data, err := syscall.Mmap(int(s.file.Fd()), 0, size, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+	if err != nil {
+		return err
+	}
+	copy(data, buf.Bytes())
+	return syscall.Munmap(data)
+}
+
+func (s *FileStore) Get(key string) (Item, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	item, found := s.items[key]
+	return item, found
+}
+
+// Set 更新内存索引后立即 flush：每次调用都会把整份 items 重新 gob 编码并
+// truncate+mmap 整个文件，代价是 O(n) 而不是 O(1)。对写多的 key 来说这样
+// 并不划算，这个 Store 更适合写少、偶尔需要持久化的场景；高频写入应该用
+// MemoryStore 配合 Cache.AutoPersist 做周期性快照，而不是每次 Set 都落盘
+func (s *FileStore) Set(key string, item Item) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.items[key] = item
+	s.flush()
+}
+
+// Delete 和 Set 一样，每次都会重新 flush 整份快照，见 Set 的注释
+func (s *FileStore) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.items, key)
+	s.flush()
+}
+
+func (s *FileStore) Range(f func(key string, item Item) bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for k, v := range s.items {
+		if !f(k, v) {
+			return
+		}
+	}
+}
+
+func (s *FileStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.items)
+}
+
+// Close 关闭底层文件
+func (s *FileStore) Close() error {
+	return s.file.Close()
+}
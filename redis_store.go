@@ -0,0 +1,212 @@
+package cache
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// RedisStore 把数据项保存到一个 Redis 实例里，每个 key 都会加上 prefix 前缀，
+// 值用 gob 编码成字符串后通过 SET/GET/DEL/KEYS 命令读写。协议是手写的最小
+// RESP 实现，不依赖任何第三方 Redis 客户端库
+type RedisStore struct {
+	mu     sync.Mutex
+	conn   net.Conn
+	reader *bufio.Reader
+	prefix string
+}
+
+// NewRedisStore 连接到 addr（形如 "127.0.0.1:6379"）对应的 Redis 实例
+func NewRedisStore(addr, keyPrefix string) (*RedisStore, error) {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return &RedisStore{
+		conn:   conn,
+		reader: bufio.NewReader(conn),
+		prefix: keyPrefix,
+	}, nil
+}
+
+func (s *RedisStore) key(k string) string {
+	return s.prefix + k
+}
+
+// do 把 args 编码成一条 RESP 数组命令发送给 Redis，并返回解析后的响应
+func (s *RedisStore) do(args ...string) (interface{}, error) {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, a := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(a), a)
+	}
+	if _, err := s.conn.Write(b.Bytes()); err != nil {
+		return nil, err
+	}
+	return s.readReply()
+}
+
+// readReply 解析一条 RESP 回复：简单字符串、错误、整数、批量字符串或数组
+func (s *RedisStore) readReply() (interface{}, error) {
+	line, err := s.reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimRight(line, "\r\n")
+	if len(line) == 0 {
+		return nil, fmt.Errorf("cache: empty redis reply")
+	}
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, fmt.Errorf("cache: redis error: %s", line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(s.reader, buf); err != nil {
+			return nil, err
+		}
+		return string(buf[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		items := make([]interface{}, n)
+		for i := 0; i < n; i++ {
+			if items[i], err = s.readReply(); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("cache: unknown redis reply type %q", line[0])
+	}
+}
+
+// encodeItem 把一个 Item 编码成可以存进 Redis 字符串值里的 gob 字节。
+// 存储 nil 值时 gob.Register(nil) 会 panic，所以跳过 nil，并且和 Cache.Save
+// 一样用 recover 兜底，避免一次注册失败拖垮整个进程
+func encodeItem(item Item) (out string, err error) {
+	defer func() {
+		if x := recover(); x != nil {
+			err = fmt.Errorf("使用Gob库注册项类型时出错")
+		}
+	}()
+	var buf bytes.Buffer
+	if item.Object != nil {
+		gob.Register(item.Object)
+	}
+	if err = gob.NewEncoder(&buf).Encode(&item); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func decodeItem(data string) (Item, error) {
+	var item Item
+	err := gob.NewDecoder(strings.NewReader(data)).Decode(&item)
+	return item, err
+}
+
+func (s *RedisStore) Get(key string) (Item, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	reply, err := s.do("GET", s.key(key))
+	if err != nil || reply == nil {
+		return Item{}, false
+	}
+	data, ok := reply.(string)
+	if !ok {
+		return Item{}, false
+	}
+	item, err := decodeItem(data)
+	if err != nil {
+		return Item{}, false
+	}
+	return item, true
+}
+
+func (s *RedisStore) Set(key string, item Item) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, err := encodeItem(item)
+	if err != nil {
+		return
+	}
+	s.do("SET", s.key(key), data)
+}
+
+func (s *RedisStore) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.do("DEL", s.key(key))
+}
+
+// Range 用 KEYS prefix* 取出所有属于这个 Store 的 key，再逐个 GET 出值；
+// 回调收到的 key 已经去掉了 prefix
+func (s *RedisStore) Range(f func(key string, item Item) bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	reply, err := s.do("KEYS", s.prefix+"*")
+	if err != nil {
+		return
+	}
+	keys, ok := reply.([]interface{})
+	if !ok {
+		return
+	}
+	for _, kv := range keys {
+		full, ok := kv.(string)
+		if !ok {
+			continue
+		}
+		valReply, err := s.do("GET", full)
+		if err != nil || valReply == nil {
+			continue
+		}
+		data, ok := valReply.(string)
+		if !ok {
+			continue
+		}
+		item, err := decodeItem(data)
+		if err != nil {
+			continue
+		}
+		if !f(strings.TrimPrefix(full, s.prefix), item) {
+			return
+		}
+	}
+}
+
+func (s *RedisStore) Len() int {
+	n := 0
+	s.Range(func(string, Item) bool {
+		n++
+		return true
+	})
+	return n
+}
+
+// Close 关闭底层的 Redis 连接
+func (s *RedisStore) Close() error {
+	return s.conn.Close()
+}
@@ -0,0 +1,199 @@
+package cache
+
+import (
+	"encoding/gob"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// ShardedCache 把数据按 key 的 FNV-1a 哈希分散到多个独立的 *Cache 分片上，
+// 每个分片各自持有自己的锁，从而避免单个 sync.RWMutex 成为高并发下的热点。
+// 它提供和 *Cache 同名同签名的 Set/Get/Add/Replace/Delete/DeleteExpired/Count/
+// Flush/Save/Load/SaveToFile/LoadFile/StopGc，只用到这些方法的地方可以直接替换；
+// Increment、Decrement、GetWithExpiration、Items、Bytes、AutoPersist 等其它
+// *Cache 方法没有提供
+type ShardedCache struct {
+	shards []*Cache
+}
+
+// shardHeader 是 ShardedCache.Save 写入流时的头部，记录分片数量，
+// 便于 Load 时按相同的分片数依次解码每个分片的数据
+type shardHeader struct {
+	ShardCount int
+}
+
+// NewShardedCache 创建一个有 shards 个分片的缓存，每个分片都是一个独立的
+// NewCache(defaultExpiration, gcInterval)，各自运行自己的过期清理 goroutine
+func NewShardedCache(shards int, defaultExpiration, gcInterval time.Duration) *ShardedCache {
+	if shards <= 0 {
+		shards = 1
+	}
+	sc := &ShardedCache{
+		shards: make([]*Cache, shards),
+	}
+	for i := 0; i < shards; i++ {
+		sc.shards[i] = NewCache(defaultExpiration, gcInterval)
+	}
+	return sc
+}
+
+// fnv32a 计算字符串的 FNV-1a 哈希，用于选择 key 所属的分片
+func fnv32a(s string) uint32 {
+	const (
+		offset32 = 2166136261
+		prime32  = 16777619
+	)
+	h := uint32(offset32)
+	for i := 0; i < len(s); i++ {
+		h ^= uint32(s[i])
+		h *= prime32
+	}
+	return h
+}
+
+// shard 返回 key 所属的分片
+func (sc *ShardedCache) shard(k string) *Cache {
+	return sc.shards[fnv32a(k)%uint32(len(sc.shards))]
+}
+
+//设置缓存数据项，如果数据存在则覆盖
+func (sc *ShardedCache) Set(k string, v interface{}, d time.Duration) {
+	sc.shard(k).Set(k, v, d)
+}
+
+//获取数据项
+func (sc *ShardedCache) Get(k string) (interface{}, bool) {
+	return sc.shard(k).Get(k)
+}
+
+//添加数据项，如果想巨响已经存在，则返回错误
+func (sc *ShardedCache) Add(k string, v interface{}, d time.Duration) error {
+	return sc.shard(k).Add(k, v, d)
+}
+
+//替换一个㛮的数据项
+func (sc *ShardedCache) Replace(k string, v interface{}, d time.Duration) error {
+	return sc.shard(k).Replace(k, v, d)
+}
+
+//删除一个数据项
+func (sc *ShardedCache) Delete(k string) {
+	sc.shard(k).Delete(k)
+}
+
+//删除过期数据项，对每个分片分别执行
+func (sc *ShardedCache) DeleteExpired() {
+	for _, c := range sc.shards {
+		c.DeleteExpired()
+	}
+}
+
+// 返回所有分片缓存数据项数量之和
+func (sc *ShardedCache) Count() int {
+	n := 0
+	for _, c := range sc.shards {
+		n += c.Count()
+	}
+	return n
+}
+
+// 清空所有分片
+func (sc *ShardedCache) Flush() {
+	for _, c := range sc.shards {
+		c.Flush()
+	}
+}
+
+//将所有分片的缓存数据写入 io.Writer 中，格式为一个 shardHeader 后面跟着
+//每个分片各自的 items map，解码时必须按相同的分片数依次读取
+func (sc *ShardedCache) Save(w io.Writer) (err error) {
+	enc := gob.NewEncoder(w)
+	defer func() {
+		if x := recover(); x != nil {
+			err = fmt.Errorf("使用Gob库注册项类型时出错")
+		}
+	}()
+
+	header := shardHeader{ShardCount: len(sc.shards)}
+	if err = enc.Encode(&header); err != nil {
+		return err
+	}
+	for _, c := range sc.shards {
+		c.mu.RLock()
+		items := map[string]Item{}
+		c.store.Range(func(k string, v Item) bool {
+			gob.Register(v.Object)
+			items[k] = v
+			return true
+		})
+		err = enc.Encode(&items)
+		c.mu.RUnlock()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//从 io.Reader 读取 Save 写入的分片数据，分片数量必须和当前 ShardedCache 一致
+func (sc *ShardedCache) Load(r io.Reader) error {
+	dec := gob.NewDecoder(r)
+	var header shardHeader
+	if err := dec.Decode(&header); err != nil {
+		return err
+	}
+	if header.ShardCount != len(sc.shards) {
+		return fmt.Errorf("分片数量不匹配：文件中为 %d，当前为 %d", header.ShardCount, len(sc.shards))
+	}
+
+	for _, c := range sc.shards {
+		items := map[string]Item{}
+		if err := dec.Decode(&items); err != nil {
+			return err
+		}
+		c.mu.Lock()
+		for k, v := range items {
+			ov, found := c.store.Get(k)
+			if !found || ov.Expired() {
+				c.store.Set(k, v)
+			}
+		}
+		c.mu.Unlock()
+	}
+	return nil
+}
+
+//保存所有分片数据到文件
+func (sc *ShardedCache) SaveToFile(file string) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	if err = sc.Save(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+//从文件加载分片数据
+func (sc *ShardedCache) LoadFile(file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	if err = sc.Load(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+//停止所有分片的过期清理 goroutine
+func (sc *ShardedCache) StopGc() {
+	for _, c := range sc.shards {
+		c.StopGc()
+	}
+}
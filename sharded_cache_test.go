@@ -0,0 +1,43 @@
+package cache
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+// BenchmarkCache_Parallel 在多个 goroutine 并发读写同一个 *Cache 时测量吞吐，
+// 作为对照组：所有请求都挤在同一把 sync.RWMutex 后面
+func BenchmarkCache_Parallel(b *testing.B) {
+	c := NewCache(NoExpiration, time.Minute)
+	defer c.StopGc()
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			k := strconv.Itoa(i)
+			c.Set(k, i, NoExpiration)
+			c.Get(k)
+			i++
+		}
+	})
+}
+
+// BenchmarkShardedCache_Parallel 是同样的并发读写负载，但落在一个有 32 个
+// 分片的 ShardedCache 上，用来衡量按 key 分片相对单锁 *Cache 带来的改善
+func BenchmarkShardedCache_Parallel(b *testing.B) {
+	sc := NewShardedCache(32, NoExpiration, time.Minute)
+	defer sc.StopGc()
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			k := strconv.Itoa(i)
+			sc.Set(k, i, NoExpiration)
+			sc.Get(k)
+			i++
+		}
+	})
+}
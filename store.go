@@ -0,0 +1,80 @@
+package cache
+
+import (
+	"fmt"
+	"time"
+)
+
+// Store 是缓存底层存储的抽象，Cache 的 Get/Set/Delete 等方法最终都落在某个
+// Store 实现上，从而可以在内存、文件、Redis 等后端之间切换而不改变调用方式
+type Store interface {
+	Get(key string) (Item, bool)
+	Set(key string, item Item)
+	Delete(key string)
+	Range(f func(key string, item Item) bool)
+	Len() int
+}
+
+// MemoryStore 是默认的存储实现，用一个普通 map 保存数据项，
+// 对应 NewCache 原本的行为
+type MemoryStore struct {
+	items map[string]Item
+}
+
+// NewMemoryStore 创建一个空的 MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{items: map[string]Item{}}
+}
+
+func (s *MemoryStore) Get(key string) (Item, bool) {
+	item, found := s.items[key]
+	return item, found
+}
+
+func (s *MemoryStore) Set(key string, item Item) {
+	s.items[key] = item
+}
+
+func (s *MemoryStore) Delete(key string) {
+	delete(s.items, key)
+}
+
+func (s *MemoryStore) Range(f func(key string, item Item) bool) {
+	for k, v := range s.items {
+		if !f(k, v) {
+			return
+		}
+	}
+}
+
+func (s *MemoryStore) Len() int {
+	return len(s.items)
+}
+
+// stores 保存按名字注册的 Store 实例，供 NewWithStore 查找
+var stores = map[string]Store{}
+
+// Register 以 name 注册一个 Store 实例，之后可以通过 NewWithStore(name, ...) 使用它，
+// 从而在不改变调用方代码的情况下切换缓存的存储后端
+func Register(name string, s Store) {
+	stores[name] = s
+}
+
+// NewWithStore 创建一个使用已注册 Store 作为后端的缓存，name 必须提前通过
+// Register 注册，否则返回错误
+func NewWithStore(name string, defaultExpiration, gcInterval time.Duration) (*Cache, error) {
+	s, found := stores[name]
+	if !found {
+		return nil, fmt.Errorf("cache: store %q is not registered", name)
+	}
+	c := &Cache{
+		defaultExpiration: defaultExpiration,
+		gcInterval:        gcInterval,
+		store:             s,
+		stopGc:            make(chan bool),
+		sampleSize:        defaultSampleSize,
+		sweepBudget:       defaultSweepBudget,
+	}
+	go c.gcLoop()
+	return c, nil
+}